@@ -0,0 +1,54 @@
+// Package errs provides location-aware errors for the todo file parser,
+// so a bad line reports path:line:col instead of losing that context in a
+// bare fmt.Errorf.
+package errs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError describes one malformed line in a todo file.
+type ParseError struct {
+	File  string
+	Line  int
+	Col   int
+	Token string
+	Cause error
+}
+
+func (e *ParseError) Error() string {
+	if e.Token != "" {
+		return fmt.Sprintf("%s:%d:%d: %v (token %q)", e.File, e.Line, e.Col, e.Cause, e.Token)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", e.File, e.Line, e.Col, e.Cause)
+}
+
+func (e *ParseError) Unwrap() error { return e.Cause }
+
+// AtToken builds a ParseError for cause at file:line:col, recording the
+// offending token, e.g. a bad checkbox marker or a mismatched indent. Pass
+// an empty token when the failure isn't tied to a specific piece of text.
+func AtToken(file string, line, col int, token string, cause error) error {
+	return newLocated(file, line, col, token, cause)
+}
+
+// MultiError collects every ParseError a caller ran into while parsing,
+// for callers (e.g. --all-errors) that want every bad line reported
+// instead of bailing out on the first.
+type MultiError struct {
+	Errs []error
+}
+
+func (m *MultiError) Error() string {
+	var b strings.Builder
+	for i, e := range m.Errs {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		b.WriteString(e.Error())
+	}
+	return b.String()
+}
+
+func (m *MultiError) Unwrap() []error { return m.Errs }
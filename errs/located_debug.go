@@ -0,0 +1,19 @@
+//go:build debug
+
+package errs
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// newLocated additionally captures the Go call site that raised the
+// error, mirroring the ErrLine wrapper pattern used elsewhere for
+// debug-tagged builds so it shows up in logs without costing anything in
+// release builds.
+func newLocated(file string, line, col int, token string, cause error) error {
+	if _, goFile, goLine, ok := runtime.Caller(2); ok {
+		cause = fmt.Errorf("%w (at %s:%d)", cause, goFile, goLine)
+	}
+	return &ParseError{File: file, Line: line, Col: col, Token: token, Cause: cause}
+}
@@ -0,0 +1,7 @@
+//go:build !debug
+
+package errs
+
+func newLocated(file string, line, col int, token string, cause error) error {
+	return &ParseError{File: file, Line: line, Col: col, Token: token, Cause: cause}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// Format renders todos in canonical form. It has no side effects, which
+// makes it the single place both the CLI and tests derive "what the file
+// should look like" from.
+func Format(todos []Todo) []byte {
+	var buf bytes.Buffer
+	PrintTodos(&buf, todos)
+	return buf.Bytes()
+}
+
+// formatMain implements the gofmt-style surface: with no flags it prints
+// canonical output to stdout, -l lists files that differ, -d prints a
+// unified diff, and -w rewrites files in place.
+func formatMain(args []string) {
+	fs := flag.NewFlagSet("todo_tracker", flag.ExitOnError)
+	list := fs.Bool("l", false, "list files whose formatting differs from todo_tracker's")
+	doDiff := fs.Bool("d", false, "display diffs of formatting changes")
+	write := fs.Bool("w", false, "overwrite file with its formatted version")
+	allErrors := fs.Bool("all-errors", false, "report all errors, not just the first")
+	fs.Parse(args)
+
+	paths := fs.Args()
+
+	if len(paths) == 0 {
+		if *list || *write {
+			fmt.Fprintln(os.Stderr, "todo_tracker: cannot use -l or -w with standard input")
+			os.Exit(1)
+		}
+		if err := formatStream(os.Stdin, os.Stdout, "<standard input>", *doDiff, *allErrors); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	exitCode := 0
+	for _, path := range paths {
+		if err := formatFile(path, *list, *doDiff, *write, *allErrors); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			exitCode = 1
+		}
+	}
+	os.Exit(exitCode)
+}
+
+func formatFile(path string, list, doDiff, write, allErrors bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	todos, err := parseTodos(bytes.NewReader(src), path, allErrors)
+	if err != nil {
+		return err
+	}
+
+	formatted := Format(todos)
+	changed := !bytes.Equal(src, formatted)
+
+	if !list && !doDiff && !write {
+		os.Stdout.Write(formatted)
+		return nil
+	}
+
+	if !changed {
+		return nil
+	}
+
+	if list {
+		fmt.Println(path)
+	}
+
+	if doDiff {
+		d, err := diffBytes(path, src, formatted)
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(d)
+	}
+
+	if write {
+		return atomicWriteFile(path, formatted)
+	}
+
+	return nil
+}
+
+func formatStream(r io.Reader, w io.Writer, name string, doDiff, allErrors bool) error {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	todos, err := parseTodos(bytes.NewReader(src), name, allErrors)
+	if err != nil {
+		return err
+	}
+
+	formatted := Format(todos)
+
+	if doDiff {
+		d, err := diffBytes(name, src, formatted)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(d)
+		return err
+	}
+
+	_, err = w.Write(formatted)
+	return err
+}
+
+// diffBytes shells out to diff(1) for a unified diff between a and b,
+// relabelling the temp file paths in the header as name.orig / name.
+func diffBytes(name string, a, b []byte) ([]byte, error) {
+	orig, err := os.CreateTemp("", "todo_tracker-orig-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(orig.Name())
+	defer orig.Close()
+
+	formatted, err := os.CreateTemp("", "todo_tracker-fmt-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(formatted.Name())
+	defer formatted.Close()
+
+	if _, err := orig.Write(a); err != nil {
+		return nil, err
+	}
+	if _, err := formatted.Write(b); err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("diff", "-u", orig.Name(), formatted.Name()).Output()
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		// diff exits 1 when the inputs differ; that's the expected case here.
+		err = nil
+	}
+
+	out = bytes.Replace(out, []byte(orig.Name()), []byte(name+".orig"), 1)
+	out = bytes.Replace(out, []byte(formatted.Name()), []byte(name), 1)
+
+	return out, err
+}
+
+// atomicWriteFile writes data to a temp file in path's directory and renames
+// it over path, so a crash mid-write never leaves path truncated.
+func atomicWriteFile(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpName := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpName)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+
+	if fi, err := os.Stat(path); err == nil {
+		os.Chmod(tmpName, fi.Mode())
+	}
+
+	return os.Rename(tmpName, path)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestFormatRoundTrip(t *testing.T) {
+	input := `- [ ] root one
+  - [X] child one
+- [X] root two
+  - [ ] child two
+`
+	roots, err := parseTodos(strings.NewReader(input), "<test>", false)
+	if err != nil {
+		t.Fatalf("parseTodos: %v", err)
+	}
+
+	formatted := Format(roots)
+
+	reparsed, err := parseTodos(bytes.NewReader(formatted), "<test>", false)
+	if err != nil {
+		t.Fatalf("parseTodos(Format(...)): %v", err)
+	}
+
+	if Format(reparsed) == nil || !bytes.Equal(formatted, Format(reparsed)) {
+		t.Fatalf("Format isn't idempotent:\nfirst:\n%s\nsecond:\n%s", formatted, Format(reparsed))
+	}
+}
+
+func TestFormatIsPure(t *testing.T) {
+	roots, err := parseTodos(strings.NewReader("- [ ] only\n"), "<test>", false)
+	if err != nil {
+		t.Fatalf("parseTodos: %v", err)
+	}
+
+	before := Format(roots)
+	Format(roots)
+	after := Format(roots)
+
+	if !bytes.Equal(before, after) {
+		t.Fatalf("Format mutated its input: first call %q, second call %q", before, after)
+	}
+}
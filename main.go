@@ -1,295 +1,126 @@
 package main
 
 import (
-	"bufio"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"os"
-	"time"
 )
 
-var progressValue float64
+// progressValues caches the last-reported completion ratio per watched
+// file so track only rewrites a file when its progress actually changed.
+var progressValues = map[string]float64{}
 
+// main dispatches to the "track" or "scan" subcommand, or otherwise treats the
+// remaining arguments as gofmt-style formatting flags.
 func main() {
-
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage %s <todo filename>", os.Args[0])
-		os.Exit(1)
-	}
-
-	todoFile := os.Args[1]
-
-	tracker := newTracker()
-
-	go func() {
-		for {
-			<-tracker.C
-			fmt.Printf("\033[H\033[J")
-			fmt.Printf("progress: %.2f\n", progress(todoFile))
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "track":
+			trackMain(os.Args[2:])
+			return
+		case "scan":
+			scanMain(os.Args[2:])
+			return
 		}
-	}()
-
-	if err := tracker.trackModifications(todoFile).Error(); err != nil {
-		log.Fatal(err)
 	}
-
+	formatMain(os.Args[1:])
 }
 
-// Tracker
-type Tracker struct {
-	timer       *time.Timer
-	lastModTime time.Time
+func trackMain(args []string) {
+	fs := flag.NewFlagSet("track", flag.ExitOnError)
+	poll := fs.Bool("poll", false, "poll for changes instead of using filesystem notifications (needed on e.g. NFS)")
+	plain := fs.Bool("plain", false, "print progress as plain text instead of the interactive view (for CI logs and pipes)")
+	fs.Parse(args)
 
-	C         chan struct{}
-	errorChan chan error
-}
-
-func newTracker() *Tracker {
-	return &Tracker{
-		timer:     time.NewTimer(time.Second * 1),
-		C:         make(chan struct{}),
-		errorChan: make(chan error),
+	paths := fs.Args()
+	if len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s track [--poll] [--plain] <todo file/dir>...\n", os.Args[0])
+		os.Exit(1)
 	}
-}
 
-func (t Tracker) Error() error {
-	err := <-t.errorChan
+	tracker, err := newTracker(*poll)
 	if err != nil {
-		return err
+		log.Fatal(err)
 	}
-	return nil
-}
+	defer tracker.Close()
+
+	if !*plain {
+		if len(paths) != 1 {
+			fmt.Fprintln(os.Stderr, "todo_tracker: the interactive view supports a single file; pass --plain to watch several at once")
+			os.Exit(1)
+		}
+
+		go func() {
+			if err := tracker.trackModifications(paths...).Error(); err != nil {
+				log.Fatal(err)
+			}
+		}()
 
-func (t *Tracker) trackModifications(filename string) *Tracker {
+		if err := runTUI(paths[0], tracker.C); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 
 	go func() {
-		for {
-			fileInfo, err := os.Stat(filename)
-			if err != nil {
-				t.errorChan <- err
+		for ev := range tracker.C {
+			fi, err := os.Stat(ev.Path)
+			if err != nil || fi.IsDir() {
 				continue
 			}
 
-			if !fileInfo.ModTime().Equal(t.lastModTime) {
-				t.C <- struct{}{}
-				t.lastModTime = fileInfo.ModTime()
+			ratio, err := trackProgress(ev.Path)
+			if err != nil {
+				log.Println(err)
+				continue
 			}
 
-			<-t.timer.C
-			t.timer.Reset(time.Second * 1)
+			fmt.Printf("\033[H\033[J")
+			fmt.Printf("%s: progress %.2f\n", ev.Path, ratio)
 		}
 	}()
 
-	return t
-
-}
-
-func progress(filename string) float64 {
-	todos, err := parseTodoFile(filename)
-	if err != nil {
+	if err := tracker.trackModifications(paths...).Error(); err != nil {
 		log.Fatal(err)
 	}
-
-	var sum, total int
-	for _, t := range todos {
-		t.CalcTodos(&t, &sum, &total, false)
-	}
-
-	// PrintTodos(os.Stdout, todos)
-
-	out := float64(sum) / float64(total) * 100
-	if progressValue != out {
-		progressValue = out
-		fd, _ := os.OpenFile(filename, os.O_WRONLY|os.O_TRUNC, 0666)
-		defer fd.Close()
-		PrintTodos(fd, todos)
-	}
-
-	return out
-}
-
-// PrintTodos
-func PrintTodos(seek io.Writer, todos []Todo) {
-	for _, t := range todos {
-		recursiveTodoPrint(seek, &t, 0)
-		fmt.Fprintf(seek, "\n")
-	}
-}
-
-func recursiveTodoPrint(seek io.Writer, todo *Todo, level int) {
-	if todo == nil {
-		return
-	}
-
-	space := ""
-	for i := 0; i < level; i++ {
-		space += " "
-	}
-	var st string
-	if todo.IsDone {
-		st = Done.String()
-	} else {
-		st = Undone.String()
-	}
-	// format := fmt.Sprintf("%%%ds\n", level)
-	fmt.Fprintf(seek, "%s%s%s\n", space, st, todo.Content)
-	level += 2
-	recursiveTodoPrint(seek, todo.sub, level)
-}
-
-// Todo
-type Todo struct {
-	IsDone   bool
-	Content  string
-	IsParent bool
-
-	sub *Todo
 }
 
-// CalcTodos
-func (t Todo) CalcTodos(current *Todo, sum, total *int, restDone bool) {
-	if current == nil {
-		return
+// trackProgress computes filename's completion ratio and, if it moved
+// since the last check, formats and atomically rewrites the file so every
+// descendant of a done todo is checked too.
+func trackProgress(filename string) (float64, error) {
+	ratio, todos, err := computeProgress(filename)
+	if err != nil {
+		return 0, err
 	}
 
-	if current.IsDone || restDone {
-		if current.IsParent {
-			restDone = true
+	if progressValues[filename] != ratio {
+		progressValues[filename] = ratio
+		if err := atomicWriteFile(filename, Format(todos)); err != nil {
+			return ratio, err
 		}
-		current.UpdateStatus(Done)
-		*sum++
 	}
 
-	*total++
-
-	t.CalcTodos(current.sub, sum, total, restDone)
+	return ratio, nil
 }
 
-func (t *Todo) UpdateStatus(st TodoStatus) {
-	t.IsDone = st == Done
+// progress reports filename's completion ratio without touching the file.
+func progress(filename string) (float64, error) {
+	ratio, _, err := computeProgress(filename)
+	return ratio, err
 }
 
-// TodoStatus
-type TodoStatus int
-
-const (
-	Done TodoStatus = iota
-	Undone
-)
-
-// String
-func (ts TodoStatus) String() string {
-	switch ts {
-	case Done:
-		return "- [X]"
-	case Undone:
-		return "- [ ]"
-	default:
-		// unreachable.
-		return ""
-	}
-}
-
-// FromString
-func (ts *TodoStatus) FromString(s string) {
-	switch s {
-	case Done.String():
-		*ts = Done
-	case Undone.String():
-		*ts = Undone
-	default:
-		// unreachable.
-		panic("FromString: unreachable")
-	}
-}
-
-func parseTodoFile(filename string) ([]Todo, error) {
-
-	fd, err := os.Open(filename)
+func computeProgress(filename string) (float64, []Todo, error) {
+	todos, err := parseTodoFile(filename, false)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
-	defer fd.Close()
-
-	reader := bufio.NewReader(fd)
-
-	todos := []Todo{}
-
-	var lineNumber int
-	for {
-		line, _, err := reader.ReadLine()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return nil, err
-		}
 
-		if string(line) == "" {
-			lineNumber++
-			continue
-		}
-
-		var subTodo bool
-		st, content := parseLine(string(line), &subTodo)
-		if st < 0 {
-			return nil, fmt.Errorf("Failed to parse TODO file [LINE %d]", lineNumber+1)
-		}
-
-		var done bool
-		switch st {
-		case Done:
-			done = true
-		case Undone:
-			done = false
-		}
-
-		if subTodo {
-			if len(todos) == 0 {
-				return nil, fmt.Errorf("Found sub todo without it parent [LINE %d]", lineNumber+1)
-			}
-			todos[len(todos)-1].IsParent = true
-			todos[len(todos)-1].sub = &Todo{
-				IsDone:  done,
-				Content: content,
-			}
-			lineNumber++
-			continue
-		}
-
-		todos = append(todos, Todo{
-			IsDone:  done,
-			Content: content,
-		})
-		lineNumber++
-
-	}
-
-	return todos, nil
-}
-
-func parseLine(line string, isSubTodo *bool) (TodoStatus, string) {
-
-	var token string
-
-	spaceCounter := 0
-	for i, c := range line {
-		if token == Done.String() || token == Undone.String() {
-			ts := new(TodoStatus)
-			ts.FromString(token)
-			if spaceCounter != 0 {
-				*isSubTodo = true
-			}
-			return *ts, line[i:]
-		}
-
-		if c == 32 && token == "" {
-			spaceCounter++
-			continue
-		}
-		token += string(c)
+	var sum, total int
+	for _, t := range todos {
+		t.CalcTodos(&t, &sum, &total, false)
 	}
 
-	return -1, ""
+	return float64(sum) / float64(total) * 100, todos, nil
 }
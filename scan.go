@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var todoCommentRe = regexp.MustCompile(`(//|#|--)\s*(TODO|FIXME)\(([^)]+)\):\s*(.+)`)
+
+// indentStep is the number of spaces reconcile nests a newly-appended
+// scanned todo under its parent.
+const indentStep = 2
+
+// Location pinpoints one occurrence of a scanned source comment.
+type Location struct {
+	File string
+	Line int
+}
+
+// SourceTodo is a TODO/FIXME comment found while scanning a source tree.
+// Comments that share the same normalized Text are folded into a single
+// SourceTodo carrying every Location it was seen at.
+type SourceTodo struct {
+	Owner     string
+	Kind      string
+	Text      string
+	Locations []Location
+}
+
+// Bugger lets an integration (filing a GitHub/Gitea issue, paging an
+// owner, ...) react to a newly discovered SourceTodo without the scanner
+// having to know anything about it. scanMain and reconcile do not call
+// Activate; no implementation is wired in.
+type Bugger interface {
+	Activate(t *SourceTodo) (handled bool, err error)
+}
+
+func scanMain(args []string) {
+	fset := flag.NewFlagSet("scan", flag.ExitOnError)
+	todoFile := fset.String("file", "TODO.md", "markdown todo file to reconcile scanned comments into")
+	parentName := fset.String("parent", "Auto-imported", "heading scanned todos are grouped under")
+	fset.Parse(args)
+
+	roots := fset.Args()
+	if len(roots) == 0 {
+		roots = []string{"."}
+	}
+
+	found, err := scanTodos(roots)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := reconcile(*todoFile, *parentName, found); err != nil {
+		log.Fatal(err)
+	}
+}
+
+type sourceMatch struct {
+	file  string
+	line  int
+	owner string
+	kind  string
+	text  string
+}
+
+// scanTodos walks roots concurrently, extracting TODO/FIXME comments. It
+// bounds concurrency to runtime.NumCPU() workers and merges duplicates
+// (matched by normalized text) into a single SourceTodo behind a mutex.
+func scanTodos(roots []string) ([]SourceTodo, error) {
+	paths := make(chan string)
+	matches := make(chan sourceMatch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < runtime.NumCPU(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				scanFile(path, matches)
+			}
+		}()
+	}
+
+	var walkErr error
+	go func() {
+		defer close(paths)
+		for _, root := range roots {
+			err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.IsDir() {
+					return nil
+				}
+				paths <- path
+				return nil
+			})
+			if err != nil {
+				walkErr = err
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(matches)
+	}()
+
+	var mu sync.Mutex
+	byText := map[string]*SourceTodo{}
+	var order []string
+
+	for m := range matches {
+		mu.Lock()
+		st, ok := byText[m.text]
+		if !ok {
+			st = &SourceTodo{Owner: m.owner, Kind: m.kind, Text: m.text}
+			byText[m.text] = st
+			order = append(order, m.text)
+		}
+		st.Locations = append(st.Locations, Location{File: m.file, Line: m.line})
+		mu.Unlock()
+	}
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	found := make([]SourceTodo, 0, len(order))
+	for _, text := range order {
+		found = append(found, *byText[text])
+	}
+	return found, nil
+}
+
+func scanFile(path string, out chan<- sourceMatch) {
+	f, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	var lineNum int
+	for sc.Scan() {
+		lineNum++
+		m := todoCommentRe.FindStringSubmatch(sc.Text())
+		if m == nil {
+			continue
+		}
+		out <- sourceMatch{
+			file:  path,
+			line:  lineNum,
+			kind:  m[2],
+			owner: m[3],
+			text:  strings.TrimSpace(m[4]),
+		}
+	}
+}
+
+// sourceTodoLineRe recognizes a markdown line previously written by
+// reconcile, so re-scanning can tell which entries came from the source
+// tree and match them back up by text.
+var sourceTodoLineRe = regexp.MustCompile(`^\s*(?:TODO|FIXME)\(([^)]+)\):\s*(.+?)\s*\([^()]*\)\s*$`)
+
+// reconcile appends newly-scanned todos under parentName in todoFile and
+// marks previously-scanned todos done once their source comment has been
+// deleted.
+func reconcile(todoFile, parentName string, found []SourceTodo) error {
+	roots, err := parseTodoFile(todoFile, false)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	parent := findOrCreateParent(&roots, parentName)
+
+	existing := map[string]*Todo{}
+	for i := range parent.Children {
+		if text := scannedTextOf(parent.Children[i].Content); text != "" {
+			existing[text] = &parent.Children[i]
+		}
+	}
+
+	foundTexts := make(map[string]bool, len(found))
+	for _, st := range found {
+		foundTexts[st.Text] = true
+	}
+
+	// Mark before appending: an append can reallocate parent.Children and
+	// leave the pointers in existing aimed at the old array.
+	for text, t := range existing {
+		if !foundTexts[text] && !t.IsDone {
+			t.UpdateStatus(Done)
+		}
+	}
+
+	for _, st := range found {
+		if _, ok := existing[st.Text]; ok {
+			continue
+		}
+		parent.Children = append(parent.Children, Todo{
+			Content: formatSourceTodo(st),
+			indent:  parent.indent + indentStep,
+		})
+	}
+
+	return atomicWriteFile(todoFile, Format(roots))
+}
+
+func findOrCreateParent(roots *[]Todo, name string) *Todo {
+	for i := range *roots {
+		if strings.TrimSpace((*roots)[i].Content) == name {
+			return &(*roots)[i]
+		}
+	}
+	*roots = append(*roots, Todo{Content: " " + name, indent: 0})
+	return &(*roots)[len(*roots)-1]
+}
+
+func scannedTextOf(content string) string {
+	m := sourceTodoLineRe.FindStringSubmatch(content)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(m[2])
+}
+
+func formatSourceTodo(st SourceTodo) string {
+	locs := make([]string, len(st.Locations))
+	for i, l := range st.Locations {
+		locs[i] = fmt.Sprintf("%s:%d", l.File, l.Line)
+	}
+	return fmt.Sprintf(" %s(%s): %s  (%s)", st.Kind, st.Owner, st.Text, strings.Join(locs, ", "))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReconcileAppendsNestedUnderParent(t *testing.T) {
+	todoFile := filepath.Join(t.TempDir(), "TODO.md")
+
+	found := []SourceTodo{
+		{Owner: "bob", Kind: "TODO", Text: "fix this thing", Locations: []Location{{File: "a.go", Line: 3}}},
+	}
+	if err := reconcile(todoFile, "Auto-imported", found); err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+
+	roots, err := parseTodoFile(todoFile, false)
+	if err != nil {
+		t.Fatalf("parseTodoFile: %v", err)
+	}
+	if len(roots) != 1 || strings.TrimSpace(roots[0].Content) != "Auto-imported" {
+		t.Fatalf("got roots %+v, want a single Auto-imported parent", roots)
+	}
+	if len(roots[0].Children) != 1 {
+		t.Fatalf("got %d children under Auto-imported, want 1", len(roots[0].Children))
+	}
+}
+
+func TestReconcileIsIdempotent(t *testing.T) {
+	todoFile := filepath.Join(t.TempDir(), "TODO.md")
+
+	found := []SourceTodo{
+		{Owner: "bob", Kind: "TODO", Text: "fix this thing", Locations: []Location{{File: "a.go", Line: 3}}},
+	}
+
+	if err := reconcile(todoFile, "Auto-imported", found); err != nil {
+		t.Fatalf("reconcile (1st): %v", err)
+	}
+	if err := reconcile(todoFile, "Auto-imported", found); err != nil {
+		t.Fatalf("reconcile (2nd): %v", err)
+	}
+
+	roots, err := parseTodoFile(todoFile, false)
+	if err != nil {
+		t.Fatalf("parseTodoFile: %v", err)
+	}
+	if len(roots) != 1 || len(roots[0].Children) != 1 {
+		t.Fatalf("re-running reconcile unchanged duplicated entries: %+v", roots)
+	}
+}
+
+func TestReconcileMarksRemovedTodosDone(t *testing.T) {
+	todoFile := filepath.Join(t.TempDir(), "TODO.md")
+
+	found := []SourceTodo{
+		{Owner: "bob", Kind: "TODO", Text: "fix this thing", Locations: []Location{{File: "a.go", Line: 3}}},
+	}
+	if err := reconcile(todoFile, "Auto-imported", found); err != nil {
+		t.Fatalf("reconcile (1st): %v", err)
+	}
+
+	if err := reconcile(todoFile, "Auto-imported", nil); err != nil {
+		t.Fatalf("reconcile (2nd, comment removed): %v", err)
+	}
+
+	roots, err := parseTodoFile(todoFile, false)
+	if err != nil {
+		t.Fatalf("parseTodoFile: %v", err)
+	}
+	if len(roots[0].Children) != 1 || !roots[0].Children[0].IsDone {
+		t.Fatalf("expected the now-missing source todo to be marked done: %+v", roots)
+	}
+}
+
+func TestFindOrCreateParentReusesExisting(t *testing.T) {
+	roots := []Todo{{Content: " Auto-imported", indent: 0}}
+	parent := findOrCreateParent(&roots, "Auto-imported")
+
+	if len(roots) != 1 {
+		t.Fatalf("got %d roots, want findOrCreateParent to reuse the existing one", len(roots))
+	}
+	if parent != &roots[0] {
+		t.Fatal("findOrCreateParent didn't return the existing parent")
+	}
+}
+
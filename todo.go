@@ -0,0 +1,326 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/t4ke0/todo_tracker/errs"
+)
+
+// Todo is a node in an N-ary tree: a todo item plus every item indented
+// underneath it.
+type Todo struct {
+	IsDone  bool
+	Content string
+
+	Children []Todo
+
+	// indent is the original leading-space count this node was parsed
+	// with, kept so PrintTodos can round-trip the user's own spacing
+	// instead of re-flowing it to a fixed step.
+	indent int
+}
+
+// CalcTodos walks current and its descendants, marking every descendant of
+// a done todo as done too (restDone), and accumulates how many of the
+// total todos are complete.
+func (t Todo) CalcTodos(current *Todo, sum, total *int, restDone bool) {
+	if current == nil {
+		return
+	}
+
+	if current.IsDone || restDone {
+		if len(current.Children) > 0 {
+			restDone = true
+		}
+		current.UpdateStatus(Done)
+		*sum++
+	}
+
+	*total++
+
+	for i := range current.Children {
+		t.CalcTodos(&current.Children[i], sum, total, restDone)
+	}
+}
+
+// countDone is CalcTodos' read-only counterpart: it reports how many of
+// current and its descendants are done, applying the same "done parent
+// implies done children" rule, but never mutates IsDone. Use this for
+// anything that only needs the count (e.g. rendering a progress bar) so
+// merely looking at a tree can't change it.
+func countDone(current *Todo, restDone bool) (sum, total int) {
+	if current == nil {
+		return 0, 0
+	}
+
+	done := current.IsDone || restDone
+	if done {
+		sum++
+		restDone = true
+	}
+	total++
+
+	for i := range current.Children {
+		s, t := countDone(&current.Children[i], restDone)
+		sum += s
+		total += t
+	}
+
+	return sum, total
+}
+
+func (t *Todo) UpdateStatus(st TodoStatus) {
+	t.IsDone = st == Done
+}
+
+// TodoStatus
+type TodoStatus int
+
+const (
+	Done TodoStatus = iota
+	Undone
+)
+
+// String
+func (ts TodoStatus) String() string {
+	switch ts {
+	case Done:
+		return "- [X]"
+	case Undone:
+		return "- [ ]"
+	default:
+		// unreachable.
+		return ""
+	}
+}
+
+// FromString sets *ts from s, returning an error instead of panicking
+// when s isn't a recognized checkbox marker.
+func (ts *TodoStatus) FromString(s string) error {
+	switch s {
+	case Done.String():
+		*ts = Done
+	case Undone.String():
+		*ts = Undone
+	default:
+		return fmt.Errorf("unrecognized todo status marker %q", s)
+	}
+	return nil
+}
+
+// PrintTodos
+func PrintTodos(seek io.Writer, todos []Todo) {
+	for _, t := range todos {
+		recursiveTodoPrint(seek, &t, 0, 0)
+		fmt.Fprintf(seek, "\n")
+	}
+}
+
+// recursiveTodoPrint writes todo and every descendant, re-deriving each
+// line's indentation as parentOutputIndent + (todo.indent - parentIndent)
+// so the file's original spacing survives a round trip even when it
+// isn't a clean multiple of two.
+func recursiveTodoPrint(seek io.Writer, todo *Todo, parentIndent, parentOutputIndent int) {
+	if todo == nil {
+		return
+	}
+
+	outputIndent := parentOutputIndent + (todo.indent - parentIndent)
+	if outputIndent < 0 {
+		outputIndent = 0
+	}
+
+	var st string
+	if todo.IsDone {
+		st = Done.String()
+	} else {
+		st = Undone.String()
+	}
+	fmt.Fprintf(seek, "%s%s%s\n", strings.Repeat(" ", outputIndent), st, todo.Content)
+
+	for i := range todo.Children {
+		recursiveTodoPrint(seek, &todo.Children[i], todo.indent, outputIndent)
+	}
+}
+
+// stackEntry tracks one open ancestor while parseTodos walks the file.
+type stackEntry struct {
+	todo   *Todo
+	indent int
+}
+
+func parseTodoFile(filename string, allErrors bool) ([]Todo, error) {
+	fd, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	return parseTodos(fd, filename, allErrors)
+}
+
+// parseTodos rebuilds the todo tree from r using an explicit indentation
+// stack: each line is attached as a child of the nearest still-open
+// ancestor with a strictly smaller indent; an unindented line becomes a
+// root, and an indented line with no open ancestor is reported as an
+// error. filename is only used to annotate errors. When allErrors is
+// set, parsing continues past a bad line and every failure is returned
+// together as an *errs.MultiError instead of stopping at the first one.
+func parseTodos(r io.Reader, filename string, allErrors bool) ([]Todo, error) {
+	sc := newLineScanner(r)
+
+	var roots []Todo
+	var stack []stackEntry
+	var badLines []error
+	prevIndent := -1
+
+	fail := func(cause error, col int, token string) (bail bool) {
+		e := errs.AtToken(filename, sc.lineNum, col, token, cause)
+		if !allErrors {
+			badLines = []error{e}
+			return true
+		}
+		badLines = append(badLines, e)
+		return false
+	}
+
+	for {
+		line, ok, err := sc.readLine()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		indent := countLeadingSpaces(line)
+		st, content, err := parseLine(line)
+		if err != nil {
+			if fail(err, indent+1, strings.TrimLeft(line, " ")) {
+				break
+			}
+			continue
+		}
+
+		if indent < prevIndent {
+			matched := indent == 0
+			for _, e := range stack {
+				if e.indent == indent {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				if fail(fmt.Errorf("dedent doesn't match any previous indentation level"), indent+1, "") {
+					break
+				}
+				continue
+			}
+		}
+		prevIndent = indent
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+
+		node := Todo{
+			IsDone:  st == Done,
+			Content: content,
+			indent:  indent,
+		}
+
+		if len(stack) == 0 {
+			if indent > 0 {
+				if fail(fmt.Errorf("found sub todo without its parent"), indent+1, "") {
+					break
+				}
+				continue
+			}
+			roots = append(roots, node)
+			stack = append(stack, stackEntry{todo: &roots[len(roots)-1], indent: indent})
+			continue
+		}
+
+		parent := stack[len(stack)-1].todo
+		parent.Children = append(parent.Children, node)
+		stack = append(stack, stackEntry{todo: &parent.Children[len(parent.Children)-1], indent: indent})
+	}
+
+	if len(badLines) > 0 {
+		if !allErrors {
+			return nil, badLines[0]
+		}
+		return roots, &errs.MultiError{Errs: badLines}
+	}
+
+	return roots, nil
+}
+
+// markerLen is the length of every checkbox marker ("- [X]"/"- [ ]"), which
+// parseLine relies on to slice off just the marker before handing it to
+// TodoStatus.FromString.
+const markerLen = len("- [X]")
+
+func parseLine(line string) (TodoStatus, string, error) {
+	trimmed := strings.TrimLeft(line, " ")
+
+	if len(trimmed) < markerLen {
+		return 0, "", fmt.Errorf("unrecognized todo marker")
+	}
+
+	var st TodoStatus
+	if err := st.FromString(trimmed[:markerLen]); err != nil {
+		return 0, "", err
+	}
+
+	return st, trimmed[markerLen:], nil
+}
+
+func countLeadingSpaces(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// lineScanner is a small readLine/unreadLine wrapper around bufio.Reader,
+// letting the indentation-stack parser peek one line ahead without
+// re-reading the underlying stream.
+type lineScanner struct {
+	r        *bufio.Reader
+	buffered *string
+	lineNum  int
+}
+
+func newLineScanner(r io.Reader) *lineScanner {
+	return &lineScanner{r: bufio.NewReader(r)}
+}
+
+func (s *lineScanner) readLine() (line string, ok bool, err error) {
+	if s.buffered != nil {
+		line, s.buffered = *s.buffered, nil
+		s.lineNum++
+		return line, true, nil
+	}
+
+	raw, _, err := s.r.ReadLine()
+	if err == io.EOF {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	s.lineNum++
+	return string(raw), true, nil
+}
+
+func (s *lineScanner) unreadLine(line string) {
+	s.buffered = &line
+	s.lineNum--
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/t4ke0/todo_tracker/errs"
+)
+
+func TestParseTodosNesting(t *testing.T) {
+	input := `- [ ] root one
+  - [ ] child one
+    - [X] grandchild
+  - [ ] child two
+- [X] root two
+`
+	roots, err := parseTodos(strings.NewReader(input), "<test>", false)
+	if err != nil {
+		t.Fatalf("parseTodos: %v", err)
+	}
+
+	if len(roots) != 2 {
+		t.Fatalf("got %d roots, want 2", len(roots))
+	}
+
+	root := roots[0]
+	if len(root.Children) != 2 {
+		t.Fatalf("root one: got %d children, want 2", len(root.Children))
+	}
+
+	grandchildren := root.Children[0].Children
+	if len(grandchildren) != 1 || grandchildren[0].Content != " grandchild" {
+		t.Fatalf("child one: got children %+v, want one grandchild", grandchildren)
+	}
+	if !grandchildren[0].IsDone {
+		t.Fatal("grandchild should be parsed as done")
+	}
+
+	if len(roots[1].Children) != 0 {
+		t.Fatalf("root two: got %d children, want 0", len(roots[1].Children))
+	}
+}
+
+func TestParseTodosDedentMismatch(t *testing.T) {
+	input := `- [ ] root
+  - [ ] child
+ - [ ] bad dedent
+`
+	_, err := parseTodos(strings.NewReader(input), "<test>", false)
+	if err == nil {
+		t.Fatal("expected a dedent-mismatch error, got nil")
+	}
+
+	pe, ok := err.(*errs.ParseError)
+	if !ok {
+		t.Fatalf("expected *errs.ParseError, got %T: %v", err, err)
+	}
+	if pe.Line != 3 {
+		t.Fatalf("got line %d, want 3", pe.Line)
+	}
+}
+
+func TestParseTodosOrphanChild(t *testing.T) {
+	input := `  - [ ] indented with no parent
+`
+	_, err := parseTodos(strings.NewReader(input), "<test>", false)
+	if err == nil {
+		t.Fatal("expected an orphan-child error, got nil")
+	}
+}
+
+func TestParseTodosAllErrorsCollectsEveryBadLine(t *testing.T) {
+	input := `- [ ] root
+  - [ ] ok child
+ - [ ] bad dedent
+* not a checkbox
+`
+	_, err := parseTodos(strings.NewReader(input), "<test>", true)
+	if err == nil {
+		t.Fatal("expected a MultiError, got nil")
+	}
+
+	me, ok := err.(*errs.MultiError)
+	if !ok {
+		t.Fatalf("got %T, want *errs.MultiError", err)
+	}
+	if len(me.Errs) != 2 {
+		t.Fatalf("got %d collected errors, want 2: %v", len(me.Errs), me.Errs)
+	}
+}
+
+func TestFromStringRejectsUnknownMarker(t *testing.T) {
+	var st TodoStatus
+	if err := st.FromString("not a marker"); err == nil {
+		t.Fatal("expected an error for an unrecognized marker")
+	}
+}
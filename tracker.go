@@ -0,0 +1,158 @@
+package main
+
+import (
+	"os"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Op describes the kind of filesystem event that triggered a tracker update.
+type Op int
+
+const (
+	Write Op = iota
+	Create
+	Remove
+	Rename
+)
+
+// Event identifies which watched file changed and how.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// Tracker watches one or more files/directories and reports changes on C.
+// By default it relies on native filesystem notifications (inotify/kqueue/
+// ReadDirectoryChangesW via fsnotify); set poll to fall back to stat-based
+// polling on filesystems that don't support notifications (e.g. NFS).
+type Tracker struct {
+	watcher *fsnotify.Watcher
+	poll    bool
+	paths   []string
+
+	C         chan Event
+	errorChan chan error
+}
+
+func newTracker(poll bool) (*Tracker, error) {
+	t := &Tracker{
+		poll:      poll,
+		C:         make(chan Event),
+		errorChan: make(chan error, 1),
+	}
+
+	if poll {
+		return t, nil
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	t.watcher = watcher
+
+	return t, nil
+}
+
+// trackModifications starts watching paths, which may mix files and
+// directories, and begins delivering events on t.C.
+func (t *Tracker) trackModifications(paths ...string) *Tracker {
+	t.paths = paths
+
+	if t.poll {
+		go t.pollLoop()
+		return t
+	}
+
+	go func() {
+		for _, p := range paths {
+			if err := t.watcher.Add(p); err != nil {
+				t.errorChan <- err
+				return
+			}
+		}
+
+		watched := make(map[string]bool, len(paths))
+		for _, p := range paths {
+			watched[p] = true
+		}
+
+		for {
+			select {
+			case ev, ok := <-t.watcher.Events:
+				if !ok {
+					return
+				}
+				op := fsnotifyOp(ev.Op)
+				if (op == Remove || op == Rename) && watched[ev.Name] {
+					// Editors and our own atomicWriteFile replace a file by
+					// writing to a temp path and renaming it over the original,
+					// which fsnotify reports as Remove/Rename on the original
+					// name and then drops the watch. Re-add it so future saves
+					// keep being reported.
+					if err := t.watcher.Add(ev.Name); err != nil {
+						t.errorChan <- err
+					}
+				}
+				t.C <- Event{Path: ev.Name, Op: op}
+			case err, ok := <-t.watcher.Errors:
+				if !ok {
+					return
+				}
+				t.errorChan <- err
+			}
+		}
+	}()
+
+	return t
+}
+
+// pollLoop is the --poll fallback: it stats every watched path once a
+// second and synthesizes a Write event whenever the mod time moves.
+func (t *Tracker) pollLoop() {
+	lastModTime := make(map[string]time.Time, len(t.paths))
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for _, p := range t.paths {
+			fi, err := os.Stat(p)
+			if err != nil {
+				t.errorChan <- err
+				continue
+			}
+			if !fi.ModTime().Equal(lastModTime[p]) {
+				lastModTime[p] = fi.ModTime()
+				t.C <- Event{Path: p, Op: Write}
+			}
+		}
+	}
+}
+
+func fsnotifyOp(op fsnotify.Op) Op {
+	switch {
+	case op&fsnotify.Write == fsnotify.Write:
+		return Write
+	case op&fsnotify.Create == fsnotify.Create:
+		return Create
+	case op&fsnotify.Remove == fsnotify.Remove:
+		return Remove
+	case op&fsnotify.Rename == fsnotify.Rename:
+		return Rename
+	default:
+		return Write
+	}
+}
+
+func (t *Tracker) Error() error {
+	return <-t.errorChan
+}
+
+func (t *Tracker) Close() error {
+	if t.watcher == nil {
+		return nil
+	}
+	return t.watcher.Close()
+}
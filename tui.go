@@ -0,0 +1,242 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// flatNode is one visible row in the tree view: the todo itself, its
+// depth for indentation, and a pointer back into the tree so toggling a
+// row mutates the real data rather than a copy.
+type flatNode struct {
+	todo  *Todo
+	depth int
+}
+
+// tuiModel is the bubbletea Model backing `track`'s interactive view: a
+// tree of todos with per-parent progress bars, kept in sync with
+// tracker.C.
+type tuiModel struct {
+	filename string
+	events   <-chan Event
+
+	roots []Todo
+	rows  []flatNode
+
+	cursor   int
+	quitting bool
+	err      error
+
+	// debounceGen counts fsEventMsg arrivals so only the debounceMsg
+	// scheduled by the most recent one triggers a reload; stale timers
+	// from earlier events in the same burst are ignored.
+	debounceGen int
+
+	barStyle    lipgloss.Style
+	cursorStyle lipgloss.Style
+}
+
+func newTUIModel(filename string, events <-chan Event) (*tuiModel, error) {
+	m := &tuiModel{
+		filename:    filename,
+		events:      events,
+		barStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+		cursorStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("212")),
+	}
+	if err := m.reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *tuiModel) reload() error {
+	roots, err := parseTodoFile(m.filename, false)
+	if err != nil {
+		return err
+	}
+
+	m.roots = roots
+	m.rows = m.rows[:0]
+	for i := range m.roots {
+		m.flatten(&m.roots[i], 0)
+	}
+
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+
+	return nil
+}
+
+func (m *tuiModel) flatten(t *Todo, depth int) {
+	m.rows = append(m.rows, flatNode{todo: t, depth: depth})
+	for i := range t.Children {
+		m.flatten(&t.Children[i], depth+1)
+	}
+}
+
+// fsEventMsg wraps a Tracker event as a bubbletea message.
+type fsEventMsg Event
+
+// debounceMsg fires once ~50ms after the last fsEventMsg, coalescing a
+// burst of editor saves into a single re-render instead of flickering
+// once per save. gen ties it back to the fsEventMsg that scheduled it, so
+// Update can tell a stale timer from the most recent one.
+type debounceMsg struct{ gen int }
+
+func waitForEvent(events <-chan Event) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		if !ok {
+			return nil
+		}
+		return fsEventMsg(ev)
+	}
+}
+
+func debounce(gen int) tea.Cmd {
+	return tea.Tick(50*time.Millisecond, func(time.Time) tea.Msg {
+		return debounceMsg{gen: gen}
+	})
+}
+
+func (m *tuiModel) Init() tea.Cmd {
+	return waitForEvent(m.events)
+}
+
+func (m *tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case fsEventMsg:
+		m.debounceGen++
+		return m, tea.Batch(waitForEvent(m.events), debounce(m.debounceGen))
+
+	case debounceMsg:
+		if msg.gen != m.debounceGen {
+			// A newer fsEventMsg arrived after this timer was scheduled;
+			// its own debounce will reload instead.
+			return m, nil
+		}
+		m.err = m.reload()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		case "j", "down":
+			if m.cursor < len(m.rows)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "r":
+			m.err = m.reload()
+		case " ":
+			m.toggleCursor()
+		}
+	}
+
+	return m, nil
+}
+
+func (m *tuiModel) toggleCursor() {
+	if len(m.rows) == 0 {
+		return
+	}
+
+	t := m.rows[m.cursor].todo
+	if t.IsDone {
+		t.UpdateStatus(Undone)
+	} else {
+		t.UpdateStatus(Done)
+	}
+
+	m.err = atomicWriteFile(m.filename, Format(m.roots))
+}
+
+func (m *tuiModel) View() string {
+	if m.quitting {
+		return ""
+	}
+
+	var b strings.Builder
+
+	if m.err != nil {
+		fmt.Fprintf(&b, "error: %v\n\n", m.err)
+	}
+
+	for i, row := range m.rows {
+		glyph := "[ ]"
+		if row.todo.IsDone {
+			glyph = "[x]"
+		}
+
+		prefix := "  "
+		line := fmt.Sprintf("%s%s %s", strings.Repeat("  ", row.depth), glyph, row.todo.Content)
+		if i == m.cursor {
+			prefix = m.cursorStyle.Render("> ")
+		}
+		b.WriteString(prefix)
+		b.WriteString(line)
+		b.WriteString("\n")
+
+		if len(row.todo.Children) > 0 {
+			var sum, total int
+			for j := range row.todo.Children {
+				s, t := countDone(&row.todo.Children[j], false)
+				sum += s
+				total += t
+			}
+			b.WriteString(strings.Repeat("  ", row.depth+1))
+			b.WriteString(m.progressBar(sum, total))
+			b.WriteString("\n")
+		}
+	}
+
+	var sum, total int
+	for i := range m.roots {
+		s, t := countDone(&m.roots[i], false)
+		sum += s
+		total += t
+	}
+	b.WriteString("\noverall ")
+	b.WriteString(m.progressBar(sum, total))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+func (m *tuiModel) progressBar(sum, total int) string {
+	const width = 20
+
+	if total == 0 {
+		return m.barStyle.Render(strings.Repeat("-", width)) + "   0%"
+	}
+
+	filled := width * sum / total
+	bar := strings.Repeat("#", filled) + strings.Repeat("-", width-filled)
+	return m.barStyle.Render(bar) + fmt.Sprintf(" %3d%%", sum*100/total)
+}
+
+// runTUI drives the interactive tree view for filename until the user
+// quits, re-rendering whenever events (already debounced by tuiModel)
+// arrive.
+func runTUI(filename string, events <-chan Event) error {
+	model, err := newTUIModel(filename, events)
+	if err != nil {
+		return err
+	}
+
+	_, err = tea.NewProgram(model).Run()
+	return err
+}